@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// attrOp is a comparison operator a predicate can assert over an
+// attribute's value.
+type attrOp int
+
+const (
+	attrEq attrOp = iota
+	attrIn
+	attrGE
+)
+
+// attrPredicate is a single assertion over one attribute, e.g.
+// "role=auditor" or "age>=18".
+type attrPredicate struct {
+	name   string
+	op     attrOp
+	values []string
+}
+
+func (p attrPredicate) satisfiedBy(value string) error {
+	switch p.op {
+	case attrEq:
+		if value != p.values[0] {
+			return errors.New(fmt.Sprintf("Attribute [%s]: expected %s, got %s", p.name, p.values[0], value))
+		}
+	case attrIn:
+		for _, v := range p.values {
+			if value == v {
+				return nil
+			}
+		}
+		return errors.New(fmt.Sprintf("Attribute [%s]: %s is not in %v", p.name, value, p.values))
+	case attrGE:
+		want, err := strconv.Atoi(p.values[0])
+		if err != nil {
+			return errors.New(fmt.Sprintf("Attribute [%s]: non-numeric threshold %s", p.name, p.values[0]))
+		}
+		got, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Attribute [%s]: non-numeric value %s", p.name, value))
+		}
+		if got < want {
+			return errors.New(fmt.Sprintf("Attribute [%s]: %d is below required %d", p.name, got, want))
+		}
+	}
+	return nil
+}
+
+// AttrPolicy declaratively states which attributes a transaction must
+// carry, what predicates those attributes must satisfy, and which of them
+// should actually be disclosed on the resulting TCert. Attributes asserted
+// but not disclosed are replaced by commitments, so the predicate can
+// still be checked without revealing the underlying value.
+type AttrPolicy struct {
+	predicates []attrPredicate
+	disclosed  map[string]bool
+}
+
+// NewAttrPolicy returns an empty AttrPolicy. Use Require and Disclose to
+// build it up.
+func NewAttrPolicy() *AttrPolicy {
+	return &AttrPolicy{disclosed: make(map[string]bool)}
+}
+
+// Require parses a predicate of the form "attr=value", "attr in {a,b,c}",
+// or "attr>=value" and adds it to the policy. It returns the policy, or
+// nil and an error if predicate is not in one of those forms.
+func (policy *AttrPolicy) Require(predicate string) (*AttrPolicy, error) {
+	p, err := parseAttrPredicate(predicate)
+	if err != nil {
+		return nil, err
+	}
+	policy.predicates = append(policy.predicates, p)
+	return policy, nil
+}
+
+// Disclose marks attrs for disclosure in plaintext on the emitted TCert.
+// Attributes asserted via Require but never passed to Disclose are
+// committed to, not revealed. It returns the policy so calls can be
+// chained.
+func (policy *AttrPolicy) Disclose(attrs ...string) *AttrPolicy {
+	for _, attr := range attrs {
+		policy.disclosed[attr] = true
+	}
+	return policy
+}
+
+// Attributes returns the names of every attribute this policy asserts a
+// predicate over, in the form expected by tCertPool.GetNextTCerts.
+func (policy *AttrPolicy) Attributes() []string {
+	attrs := make([]string, len(policy.predicates))
+	for i, p := range policy.predicates {
+		attrs[i] = p.name
+	}
+	return attrs
+}
+
+// IsDisclosed reports whether attr should appear in plaintext on the TCert
+// rather than behind a commitment.
+func (policy *AttrPolicy) IsDisclosed(attr string) bool {
+	return policy.disclosed[attr]
+}
+
+// Verify checks values, a map of attribute name to asserted plaintext
+// value, against every predicate in the policy. It is the helper an
+// endorser uses once it has opened the commitments on a TCert's
+// undisclosed attributes.
+func (policy *AttrPolicy) Verify(values map[string]string) error {
+	for _, p := range policy.predicates {
+		value, ok := values[p.name]
+		if !ok {
+			return errors.New(fmt.Sprintf("Missing required attribute [%s]", p.name))
+		}
+		if err := p.satisfiedBy(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseAttrPredicate(predicate string) (attrPredicate, error) {
+	switch {
+	case strings.Contains(predicate, ">="):
+		parts := strings.SplitN(predicate, ">=", 2)
+		return attrPredicate{name: strings.TrimSpace(parts[0]), op: attrGE, values: []string{strings.TrimSpace(parts[1])}}, nil
+
+	case strings.Contains(predicate, " in "):
+		parts := strings.SplitN(predicate, " in ", 2)
+		set := strings.TrimSpace(parts[1])
+		set = strings.TrimPrefix(set, "{")
+		set = strings.TrimSuffix(set, "}")
+		values := strings.Split(set, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		return attrPredicate{name: strings.TrimSpace(parts[0]), op: attrIn, values: values}, nil
+
+	case strings.Contains(predicate, "="):
+		parts := strings.SplitN(predicate, "=", 2)
+		return attrPredicate{name: strings.TrimSpace(parts[0]), op: attrEq, values: []string{strings.TrimSpace(parts[1])}}, nil
+	}
+
+	return attrPredicate{}, errors.New(fmt.Sprintf("Unrecognized attribute predicate: %s", predicate))
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import "testing"
+
+func TestAttrPolicyVerify(t *testing.T) {
+	policy := NewAttrPolicy()
+	if _, err := policy.Require("role=auditor"); err != nil {
+		t.Fatalf("Require(role=auditor) returned error: %s", err)
+	}
+	if _, err := policy.Require("region in {EU,US}"); err != nil {
+		t.Fatalf("Require(region in {EU,US}) returned error: %s", err)
+	}
+	if _, err := policy.Require("age>=18"); err != nil {
+		t.Fatalf("Require(age>=18) returned error: %s", err)
+	}
+	policy.Disclose("role")
+
+	if !policy.IsDisclosed("role") {
+		t.Errorf("expected role to be disclosed")
+	}
+	if policy.IsDisclosed("region") {
+		t.Errorf("expected region to not be disclosed")
+	}
+
+	if err := policy.Verify(map[string]string{"role": "auditor", "region": "EU", "age": "21"}); err != nil {
+		t.Errorf("expected policy to be satisfied, got: %s", err)
+	}
+
+	if err := policy.Verify(map[string]string{"role": "guest", "region": "EU", "age": "21"}); err == nil {
+		t.Errorf("expected mismatched role to fail verification")
+	}
+
+	if err := policy.Verify(map[string]string{"role": "auditor", "region": "APAC", "age": "21"}); err == nil {
+		t.Errorf("expected region outside {EU,US} to fail verification")
+	}
+
+	if err := policy.Verify(map[string]string{"role": "auditor", "region": "EU", "age": "17"}); err == nil {
+		t.Errorf("expected age below threshold to fail verification")
+	}
+
+	if err := policy.Verify(map[string]string{"role": "auditor", "region": "EU"}); err == nil {
+		t.Errorf("expected missing attribute to fail verification")
+	}
+}
+
+func TestParseAttrPredicateInvalid(t *testing.T) {
+	policy := NewAttrPolicy()
+	if _, err := policy.Require("not a predicate"); err == nil {
+		t.Errorf("expected an unrecognized predicate to return an error")
+	}
+}
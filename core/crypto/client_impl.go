@@ -19,6 +19,8 @@ package crypto
 import (
 	"errors"
 
+	"golang.org/x/net/context"
+
 	"github.com/hyperledger/fabric/core/crypto/primitives"
 	"github.com/hyperledger/fabric/core/crypto/utils"
 	obc "github.com/hyperledger/fabric/protos"
@@ -36,6 +38,7 @@ type clientImpl struct {
 	// TCA KDFKey
 	tCertOwnerKDFKey []byte
 	tCertPool        tCertPool
+	tCertRenewer     *tCertRenewer
 }
 
 // NewChaincodeDeployTransaction is used to deploy chaincode.
@@ -179,6 +182,46 @@ func (client *clientImpl) GetTCertificateHandlerNext(attributes ...string) (Cert
 	return handler, nil
 }
 
+// GetTCertificateHandlerWithPolicy returns a CertificateHandler whose
+// TCert is bound to the attribute set declared in policy: attributes
+// required via AttrPolicy.Require are asserted on the TCert, but only
+// those also passed to AttrPolicy.Disclose are revealed in plaintext,
+// the rest are replaced by commitments. This makes the existing
+// attributes ...string surface enforceable rather than advisory.
+func (client *clientImpl) GetTCertificateHandlerWithPolicy(policy *AttrPolicy) (CertificateHandler, error) {
+	// Verify that the client is initialized
+	if !client.isInitialized {
+		return nil, utils.ErrNotInitialized
+	}
+
+	if policy == nil {
+		return nil, errors.New("Failed to obtain a CertificateHandler, policy cannot be nil")
+	}
+
+	// Get next TCert, asserting the policy's attributes and disclosing
+	// only the subset it marked for disclosure
+	tBlocks, err := client.tCertPool.GetNextTCertsWithDisclosure(1, policy.Attributes(), policy.disclosed)
+	if err != nil {
+		client.error("Failed to obtain a (not yet used) TCert for creating a CertificateHandler [%s].", err.Error())
+		return nil, err
+	}
+
+	if len(tBlocks) != 1 {
+		client.error("Failed to obtain a TCert for creating a CertificateHandler.")
+		return nil, errors.New("Failed to obtain a TCert for creating a CertificateHandler")
+	}
+
+	// Return the handler
+	handler := &tCertHandlerImpl{}
+	err = handler.init(client, tBlocks[0].tCert)
+	if err != nil {
+		client.error("Failed getting handler [%s].", err.Error())
+		return nil, err
+	}
+
+	return handler, nil
+}
+
 // GetTCertHandlerFromDER returns a CertificateHandler whose certificate is the one passed
 func (client *clientImpl) GetTCertificateHandlerFromDER(tCertDER []byte) (CertificateHandler, error) {
 	// Verify that the client is initialized
@@ -265,7 +308,21 @@ func (client *clientImpl) init(id string, pwd []byte) error {
 	return nil
 }
 
+// StartTCertRenewal launches a background goroutine that proactively
+// renews TCerts in the pool as they approach expiry, using keyGen to
+// produce the fresh keypair each renewed TCert is bound to. It is
+// optional: callers that never invoke it get today's behavior of TCerts
+// simply running out and being re-requested from the pool.
+func (client *clientImpl) StartTCertRenewal(ctx context.Context, keyGen KeyGenerator) {
+	client.tCertRenewer = newTCertRenewer(client, keyGen)
+	client.tCertRenewer.start(ctx)
+}
+
 func (client *clientImpl) close() (err error) {
+	if client.tCertRenewer != nil {
+		client.tCertRenewer.stop()
+	}
+
 	if client.tCertPool != nil {
 		if err = client.tCertPool.Stop(); err != nil {
 			client.debug("Failed closing TCertPool [%s]", err)
@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	"errors"
+
+	"golang.org/x/net/context"
+)
+
+// Renew rotates the TCert backing this handler in place: it asks the
+// client to renew the handler's current TCert with a freshly generated
+// key and re-initializes the handler against the result, so that callers
+// holding onto a CertificateHandler keep working across the swap instead
+// of having to fetch a new handler.
+func (handler *tCertHandlerImpl) Renew(ctx context.Context, keyGen KeyGenerator) error {
+	pk, _, err := keyGen()
+	if err != nil {
+		return err
+	}
+
+	renewed, err := handler.client.RenewTCertsContext(ctx, []tCert{handler.tCert}, pk)
+	if err != nil {
+		return err
+	}
+
+	if len(renewed) != 1 {
+		return errors.New("Failed renewing TCert for CertificateHandler. Expected exactly one returned TCert.")
+	}
+
+	return handler.init(handler.client, renewed[0])
+}
@@ -0,0 +1,143 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crypto
+
+import (
+	stdcrypto "crypto"
+	"errors"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/core/crypto/utils"
+)
+
+// renewSkew is how far ahead of a TCert's NotAfter the renewer starts
+// trying to replace it, so that in-flight transactions never race a cert
+// that just expired.
+const renewSkew = 1 * time.Minute
+
+// renewPollInterval is how often the renewer wakes up to scan the pool
+// for TCerts approaching expiry.
+const renewPollInterval = 30 * time.Second
+
+// KeyGenerator produces a fresh keypair to bind a renewed TCert to. It is
+// supplied by the caller so that the renewer never has to know how keys
+// are generated or stored.
+type KeyGenerator func() (stdcrypto.PublicKey, stdcrypto.PrivateKey, error)
+
+// tCertRenewer watches a tCertPool for certificates nearing expiry and
+// proactively renews them in place using a caller-supplied KeyGenerator.
+type tCertRenewer struct {
+	client *clientImpl
+	keyGen KeyGenerator
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newTCertRenewer creates a renewer for client's tCertPool. Call start to
+// begin the background goroutine and close (via the client) to stop it.
+func newTCertRenewer(client *clientImpl, keyGen KeyGenerator) *tCertRenewer {
+	return &tCertRenewer{client: client, keyGen: keyGen}
+}
+
+// start launches the background renewal goroutine. It returns immediately;
+// the goroutine runs until ctx is cancelled.
+func (r *tCertRenewer) start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(renewPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.renewExpiring(ctx)
+			}
+		}
+	}()
+}
+
+// stop cancels the renewer goroutine and waits for it to exit.
+func (r *tCertRenewer) stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+}
+
+// renewExpiring scans the pool for TCerts whose NotAfter is within
+// renewSkew and replaces each one, evicting the old cert once its
+// replacement is in place. It relies on two additions to the tCertPool
+// interface: AttributesAndCerts, to enumerate the certs currently held by
+// the pool, and RenewTCert, to swap one out for a fresh one bound to the
+// given public key.
+func (r *tCertRenewer) renewExpiring(ctx context.Context) {
+	expiring := r.client.tCertPool.AttributesAndCerts()
+	cutoff := time.Now().Add(renewSkew)
+
+	for _, tc := range expiring {
+		if tc.GetCertificate().NotAfter.After(cutoff) {
+			continue
+		}
+
+		pk, _, err := r.keyGen()
+		if err != nil {
+			r.client.error("Failed generating rekey material for TCert renewal [%s].", err.Error())
+			continue
+		}
+
+		if _, err := r.client.RenewTCertsContext(ctx, []tCert{tc}, pk); err != nil {
+			r.client.error("Failed renewing TCert [%s].", err.Error())
+			continue
+		}
+	}
+}
+
+// RenewTCertsContext presents an existing (possibly near-expiring) TCert
+// together with a fresh public key and returns a new TCert bound to that
+// key, without the client going back through full enrollment.
+func (client *clientImpl) RenewTCertsContext(ctx context.Context, oldCerts []tCert, pk stdcrypto.PublicKey) ([]tCert, error) {
+	if !client.isInitialized {
+		return nil, utils.ErrNotInitialized
+	}
+
+	if len(oldCerts) == 0 {
+		return nil, errors.New("No TCerts supplied to renew.")
+	}
+
+	renewed := make([]tCert, len(oldCerts))
+	for i, old := range oldCerts {
+		newCert, err := client.tCertPool.RenewTCert(old, pk)
+		if err != nil {
+			client.error("Failed renewing TCert [%s].", err.Error())
+			return nil, err
+		}
+		renewed[i] = newCert
+	}
+
+	return renewed, nil
+}
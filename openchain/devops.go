@@ -36,10 +36,17 @@ var devops_logger = logging.MustGetLogger("devops")
 
 func NewDevopsServer() *devops {
 	d := new(devops)
+	d.endorser = NewEndorser(newVMSupport())
+	d.versions = newVersionRegistry()
 	return d
 }
 
 type devops struct {
+	endorser *Endorser
+	versions *versionRegistry
+	// versionPolicy, when set, overrides the default per-deploy caret
+	// range derived from the currently deployed version.
+	versionPolicy *VersionPolicy
 }
 
 func (*devops) Build(context context.Context, spec *pb.ChainletSpec) (*pb.BuildResult, error) {
@@ -51,14 +58,25 @@ func (*devops) Build(context context.Context, spec *pb.ChainletSpec) (*pb.BuildR
 	if err := checkSpec(spec); err != nil {
 		return nil, err
 	}
+	builder, err := getPlatformBuilder(spec.Type)
+	if err != nil {
+		devops_logger.Error("Error selecting platform builder: %s", err)
+		return nil, err
+	}
+	dockerfile, err := builder.Dockerfile(spec)
+	if err != nil {
+		devops_logger.Error("Error generating %s Dockerfile: %s", builder.Name(), err)
+		return nil, err
+	}
+	devops_logger.Debug("Dispatching build to %s platform builder", builder.Name())
 	// Get new VM and as for building of container image
 	vm, err := NewVM()
 	if err != nil {
 		devops_logger.Error("Error getting VM: %s", err)
 		return nil, err
 	}
-	// Build the spec
-	if _, err := vm.BuildChaincodeContainer(spec); err != nil {
+	// Build the spec using the language-specific Dockerfile
+	if _, err := vm.BuildChaincodeContainer(spec, dockerfile); err != nil {
 		devops_logger.Error("Error getting VM: %s", err)
 		return nil, err
 	}
@@ -68,38 +86,88 @@ func (*devops) Build(context context.Context, spec *pb.ChainletSpec) (*pb.BuildR
 	return result, nil
 }
 
-func (*devops) makeVersion(version string) (string, error) {
-	// v1, err := semver.Make("1.0.0-beta")
-	// v2, err := semver.Make("2.0.0-beta")
-	// v1.Compare(v2)
-	return "", nil
+func (d *devops) Deploy(ctx context.Context, spec *pb.ChainletSpec) (*pb.DevopsResponse, error) {
+	if err := checkSpec(spec); err != nil {
+		devops_logger.Error("Invalid spec: %v\n\n error: %s", spec, err)
+		return nil, err
+	}
+
+	if err := d.checkVersionCompatibility(spec); err != nil {
+		devops_logger.Error("Rejecting deploy for %v: %s", spec.ChainletID, err)
+		return nil, err
+	}
+
+	signedProp := &SignedProposal{
+		Proposal: &Proposal{ChainletSpec: spec},
+	}
+
+	resp, err := d.endorser.ProcessProposal(ctx, signedProp)
+	if err != nil {
+		devops_logger.Error("Deploy proposal for %v rejected: %s", spec.ChainletID, err)
+		return nil, err
+	}
+
+	devops_logger.Debug("Endorsed deploy proposal for %v", spec.ChainletID)
+	// resp.Endorsement is not a real signature yet (see vmSupport.Sign's
+	// SECURITY comment), so it is surfaced labeled as unsigned rather than
+	// as proof of endorsement.
+	return &pb.DevopsResponse{
+		Status: resp.Status,
+		Msg:    fmt.Sprintf("Deployed %s, unsigned endorsement placeholder: %x", spec.ChainletID.Url, resp.Endorsement),
+	}, nil
 }
 
-func (*devops) Deploy(ctx context.Context, spec *pb.ChainletSpec) (*pb.DevopsResponse, error) {
-	response := &pb.DevopsResponse{Status: pb.DevopsResponse_SUCCESS, Msg: "Good to go"}
-	err := checkSpec(spec)
+// checkVersionCompatibility consults d.versionPolicy before allowing spec
+// to be (re)deployed over whatever is currently deployed against the same
+// ChainletID.Url, then records spec's version as the new deployed one.
+func (d *devops) checkVersionCompatibility(spec *pb.ChainletSpec) error {
+	next, err := semver.Make(spec.ChainletID.Version)
+	if err != nil {
+		return err
+	}
+
+	minCompatible := next
+	if spec.MinCompatibleVersion != "" {
+		minCompatible, err = semver.Make(spec.MinCompatibleVersion)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid MinCompatibleVersion '%s': %s", spec.MinCompatibleVersion, err))
+		}
+	}
+
+	return d.versions.checkAndRecord(spec.ChainletID.Url, next, minCompatible, d.versionPolicy, spec.Force)
+}
+
+// Version is returned by GetDeployedVersion, mirroring the message a
+// devops.proto addition would declare for this RPC.
+type Version struct {
+	Version string
+}
+
+// GetDeployedVersion returns the version currently deployed against id,
+// so operators and migration tooling can check compatibility before
+// pushing an upgrade.
+func (d *devops) GetDeployedVersion(ctx context.Context, id *pb.ChainletID) (*Version, error) {
+	version, err := d.versions.get(id.Url)
 	if err != nil {
-		devops_logger.Error("Invalid spec: %v\n\n error: %s", spec, err)
 		return nil, err
 	}
-	//devops_logger.Debug("returning status: %s", status)
-	return response, nil
+	return &Version{Version: version.String()}, nil
 }
 
 // Checks to see if chaincode resides within current package capture for language.
 func checkSpec(spec *pb.ChainletSpec) error {
 
-	// Only allow GOLANG type at the moment
-	if spec.Type != pb.ChainletSpec_GOLANG {
-		return errors.New(fmt.Sprintf("Only support '%s' currently", pb.ChainletSpec_GOLANG))
+	validator, err := getSpecValidator(spec.Type)
+	if err != nil {
+		return err
 	}
-	if err := checkGolangSpec(spec); err != nil {
+	if err := validator.Validate(spec); err != nil {
 		return err
 	}
 	devops_logger.Debug("Validated spec:  %v", spec)
 
 	// Check the version
-	_, err := semver.Make(spec.ChainletID.Version)
+	_, err = semver.Make(spec.ChainletID.Version)
 	return err
 }
 
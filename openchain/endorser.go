@@ -0,0 +1,216 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package openchain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+
+	pb "github.com/openblockchain/obc-peer/protos"
+)
+
+var endorser_logger = logging.MustGetLogger("endorser")
+
+// Proposal is the deserialized content of a SignedProposal: the chainlet
+// being invoked together with the identity that is asking for it.
+type Proposal struct {
+	ChainletSpec *pb.ChainletSpec
+	Creator      []byte
+}
+
+// SignedProposal carries a serialized Proposal and the signature the
+// submitter produced over it, the unit of work an Endorser operates on.
+type SignedProposal struct {
+	Proposal  *Proposal
+	Signature []byte
+}
+
+// ReadWriteSet captures the keys read and written while simulating a
+// proposal, so that independent endorsers' results can later be compared
+// before being assembled into a transaction.
+type ReadWriteSet struct {
+	Reads  map[string][]byte
+	Writes map[string][]byte
+}
+
+// ProposalResponse is the outcome of endorsing a SignedProposal: the
+// simulation payload together with this peer's endorsement over it.
+//
+// Endorsement is only as trustworthy as the Support.Sign implementation
+// that produced it; vmSupport's Sign does not actually sign anything yet
+// (see its SECURITY comment), so callers must not treat Endorsement as
+// proof of endorsement until a real signing Support is wired in.
+type ProposalResponse struct {
+	Status      pb.DevopsResponse_StatusCode
+	Payload     []byte
+	Endorsement []byte
+}
+
+// Support supplies the backing services an Endorser needs in order to
+// look up, simulate, and sign a proposal. A real peer wires this to its
+// chaincode registry, ledger, and local identity; tests can stub it out.
+type Support interface {
+	// Chaincode returns the deployment spec addressed by id, or an error
+	// if it is not known to the peer.
+	Chaincode(id *pb.ChainletID) (*pb.ChainletSpec, error)
+
+	// Simulate executes spec without committing it and returns the
+	// read/write set it produced.
+	Simulate(spec *pb.ChainletSpec) (*ReadWriteSet, error)
+
+	// Sign produces this peer's endorsement signature over payload.
+	Sign(payload []byte) ([]byte, error)
+}
+
+// AuthFilter is run, in order, over every proposal before it reaches
+// simulation. A filter returning an error aborts the proposal and short
+// circuits the remaining chain.
+type AuthFilter interface {
+	Filter(ctx context.Context, proposal *SignedProposal) error
+}
+
+// chainFilters holds the ordered AuthFilter chain applied to incoming
+// proposals, populated by InitRegistry from peer configuration.
+var chainFilters []AuthFilter
+
+// InitRegistry wires up the configured AuthFilter chain. It is called once
+// at peer startup; callers may invoke it again to substitute a different
+// chain, e.g. in tests.
+func InitRegistry(filters ...AuthFilter) {
+	chainFilters = filters
+}
+
+// Endorser simulates proposals against a Support implementation and signs
+// the resulting read/write set, modeled on Fabric's EndorserServer.
+type Endorser struct {
+	support Support
+}
+
+// NewEndorser creates an Endorser backed by the given Support.
+func NewEndorser(support Support) *Endorser {
+	return &Endorser{support: support}
+}
+
+// ProcessProposal runs the registered AuthFilter chain over signedProp,
+// simulates the chainlet invocation it carries, and returns a signed
+// ProposalResponse.
+func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *SignedProposal) (*ProposalResponse, error) {
+	if signedProp == nil || signedProp.Proposal == nil {
+		return nil, errors.New("Error in ProcessProposal, expected signed proposal, nil received")
+	}
+
+	for _, filter := range chainFilters {
+		if err := filter.Filter(ctx, signedProp); err != nil {
+			endorser_logger.Error("Proposal rejected by filter: %s", err)
+			return nil, err
+		}
+	}
+
+	spec := signedProp.Proposal.ChainletSpec
+	if spec == nil {
+		return nil, errors.New("Error in ProcessProposal, proposal carries no chainlet spec")
+	}
+
+	if _, err := e.support.Chaincode(spec.ChainletID); err != nil {
+		endorser_logger.Debug("Chainlet %s not yet deployed, proceeding with deploy proposal: %s", spec.ChainletID, err)
+	}
+
+	rwset, err := e.support.Simulate(spec)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error simulating proposal: %s", err))
+	}
+
+	payload := []byte(fmt.Sprintf("%v", rwset.Writes))
+
+	sig, err := e.support.Sign(payload)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error endorsing proposal: %s", err))
+	}
+
+	return &ProposalResponse{
+		Status:      pb.DevopsResponse_SUCCESS,
+		Payload:     payload,
+		Endorsement: sig,
+	}, nil
+}
+
+// vmSupport is the default Support, backed by the peer's own VM for
+// simulating chaincode builds and an in-memory map of what has been
+// deployed so far. It is reachable concurrently from gRPC Deploy calls,
+// so access to deployed is guarded by mu.
+type vmSupport struct {
+	mu       sync.RWMutex
+	deployed map[string]*pb.ChainletSpec
+}
+
+func newVMSupport() *vmSupport {
+	return &vmSupport{deployed: make(map[string]*pb.ChainletSpec)}
+}
+
+func (s *vmSupport) Chaincode(id *pb.ChainletID) (*pb.ChainletSpec, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	spec, ok := s.deployed[id.Url]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Chainlet %s is not deployed", id.Url))
+	}
+	return spec, nil
+}
+
+func (s *vmSupport) Simulate(spec *pb.ChainletSpec) (*ReadWriteSet, error) {
+	builder, err := getPlatformBuilder(spec.Type)
+	if err != nil {
+		return nil, err
+	}
+	dockerfile, err := builder.Dockerfile(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := NewVM()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := vm.BuildChaincodeContainer(spec, dockerfile); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.deployed[spec.ChainletID.Url] = spec
+	s.mu.Unlock()
+
+	return &ReadWriteSet{
+		Writes: map[string][]byte{spec.ChainletID.Url: []byte(spec.ChainletID.Version)},
+	}, nil
+}
+
+func (s *vmSupport) Sign(payload []byte) ([]byte, error) {
+	// SECURITY: this is NOT a signature. It returns payload unchanged as a
+	// placeholder until this peer's enrollment certificate is wired in
+	// here, so every ProposalResponse.Endorsement produced through
+	// vmSupport is unsigned and must not be trusted as proof of
+	// endorsement by any caller. TODO: sign with the peer's identity.
+	return payload, nil
+}
@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package openchain
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/openblockchain/obc-peer/protos"
+)
+
+// PlatformBuilder knows how to turn a chainlet of a particular language
+// into the Dockerfile vm.BuildChaincodeContainer bakes into the container
+// image.
+type PlatformBuilder interface {
+	Name() string
+	Dockerfile(spec *pb.ChainletSpec) (string, error)
+}
+
+type golangPlatformBuilder struct{}
+
+func (golangPlatformBuilder) Name() string { return "golang" }
+
+func (golangPlatformBuilder) Dockerfile(spec *pb.ChainletSpec) (string, error) {
+	return fmt.Sprintf("FROM openchain-baseimage\nCOPY . $GOPATH/src/%s\nRUN go build -o /chaincode %s\n", spec.ChainletID.Url, spec.ChainletID.Url), nil
+}
+
+type javaPlatformBuilder struct{}
+
+func (javaPlatformBuilder) Name() string { return "java" }
+
+func (javaPlatformBuilder) Dockerfile(spec *pb.ChainletSpec) (string, error) {
+	return "FROM openjdk:8-jre\nCOPY . /chaincode\nRUN cd /chaincode && ./mvnw -q -DskipTests package\n", nil
+}
+
+type nodePlatformBuilder struct{}
+
+func (nodePlatformBuilder) Name() string { return "node" }
+
+func (nodePlatformBuilder) Dockerfile(spec *pb.ChainletSpec) (string, error) {
+	return "FROM node:6\nCOPY . /chaincode\nRUN cd /chaincode && npm install --production\n", nil
+}
+
+// platformBuilders is keyed by ChainletSpec_Type so Build can dispatch to
+// the right PlatformBuilder for the chainlet it was asked to package.
+var platformBuilders = map[pb.ChainletSpec_Type]PlatformBuilder{
+	pb.ChainletSpec_GOLANG: golangPlatformBuilder{},
+	pb.ChainletSpec_JAVA:   javaPlatformBuilder{},
+	pb.ChainletSpec_NODE:   nodePlatformBuilder{},
+}
+
+// getPlatformBuilder returns the PlatformBuilder registered for t, or an
+// error if the language has no builder yet.
+func getPlatformBuilder(t pb.ChainletSpec_Type) (PlatformBuilder, error) {
+	builder, ok := platformBuilders[t]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("No platform builder registered for '%s'", t))
+	}
+	return builder, nil
+}
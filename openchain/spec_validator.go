@@ -0,0 +1,100 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package openchain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	pb "github.com/openblockchain/obc-peer/protos"
+)
+
+// SpecValidator checks that a ChainletSpec's source is present and
+// structured the way its declared language expects, before a build is
+// ever attempted.
+type SpecValidator interface {
+	Validate(spec *pb.ChainletSpec) error
+}
+
+// specValidators is keyed by ChainletSpec_Type so checkSpec can dispatch
+// to the right validator without a language switch.
+var specValidators = map[pb.ChainletSpec_Type]SpecValidator{}
+
+func init() {
+	specValidators[pb.ChainletSpec_GOLANG] = golangSpecValidator{}
+	specValidators[pb.ChainletSpec_JAVA] = javaSpecValidator{}
+	specValidators[pb.ChainletSpec_NODE] = nodeSpecValidator{}
+}
+
+// getSpecValidator returns the SpecValidator registered for t, or an
+// error if the language is not supported.
+func getSpecValidator(t pb.ChainletSpec_Type) (SpecValidator, error) {
+	validator, ok := specValidators[t]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Chaincode type '%s' is not supported", t))
+	}
+	return validator, nil
+}
+
+type golangSpecValidator struct{}
+
+func (golangSpecValidator) Validate(spec *pb.ChainletSpec) error {
+	return checkGolangSpec(spec)
+}
+
+type javaSpecValidator struct{}
+
+func (javaSpecValidator) Validate(spec *pb.ChainletSpec) error {
+	root := filepath.Join(os.Getenv("GOPATH"), "src", spec.ChainletID.Url)
+	return checkPackagingRoot(root, "pom.xml", "build.gradle")
+}
+
+type nodeSpecValidator struct{}
+
+func (nodeSpecValidator) Validate(spec *pb.ChainletSpec) error {
+	root := filepath.Join(os.Getenv("GOPATH"), "src", spec.ChainletID.Url)
+	return checkPackagingRoot(root, "package.json")
+}
+
+// checkPackagingRoot verifies that root exists and contains at least one
+// of the given marker files identifying its build system.
+func checkPackagingRoot(root string, markers ...string) error {
+	exists, err := pathExists(root)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error validating chaincode path: %s", err))
+	}
+	if !exists {
+		return errors.New(fmt.Sprintf("Path to chaincode does not exist: %s", root))
+	}
+
+	for _, marker := range markers {
+		found, err := pathExists(filepath.Join(root, marker))
+		if err != nil {
+			return errors.New(fmt.Sprintf("Error validating chaincode path: %s", err))
+		}
+		if found {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("No recognized packaging descriptor (%v) found under %s", markers, root))
+}
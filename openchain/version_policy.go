@@ -0,0 +1,189 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package openchain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver"
+)
+
+// VersionPolicy gates which chaincode versions may be (re)deployed over
+// an existing one, expressed as a semver range such as "^1.2.0" or
+// ">=2.0.0 <3.0.0".
+type VersionPolicy struct {
+	constraint string
+	allowed    semver.Range
+}
+
+// NewVersionPolicy parses constraint into a VersionPolicy. blang/semver's
+// ParseRange has no notion of caret ranges, so a leading "^" is expanded
+// to its equivalent >=/< range, following npm's caret semantics
+// (^1.2.3 -> >=1.2.3 <2.0.0, ^0.2.3 -> >=0.2.3 <0.3.0, ^0.0.3 -> >=0.0.3
+// <0.0.4), before being handed to semver.ParseRange.
+func NewVersionPolicy(constraint string) (*VersionPolicy, error) {
+	expanded, err := expandCaret(constraint)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid version constraint '%s': %s", constraint, err))
+	}
+
+	allowed, err := semver.ParseRange(expanded)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid version constraint '%s': %s", constraint, err))
+	}
+	return &VersionPolicy{constraint: constraint, allowed: allowed}, nil
+}
+
+// expandCaret rewrites a caret constraint ("^1.2.3") into the >=/< range
+// it denotes. Constraints without a leading "^" are returned unchanged.
+func expandCaret(constraint string) (string, error) {
+	trimmed := strings.TrimSpace(constraint)
+	if !strings.HasPrefix(trimmed, "^") {
+		return constraint, nil
+	}
+
+	v, err := semver.Make(strings.TrimPrefix(trimmed, "^"))
+	if err != nil {
+		return "", err
+	}
+	return caretRange(v), nil
+}
+
+// caretRange returns the widest >=/< range that leaves v's leftmost
+// non-zero component unchanged, i.e. the range npm's "^" denotes.
+func caretRange(v semver.Version) string {
+	switch {
+	case v.Major > 0:
+		return fmt.Sprintf(">=%d.%d.%d <%d.0.0", v.Major, v.Minor, v.Patch, v.Major+1)
+	case v.Minor > 0:
+		return fmt.Sprintf(">=%d.%d.%d <%d.%d.0", v.Major, v.Minor, v.Patch, v.Major, v.Minor+1)
+	default:
+		return fmt.Sprintf(">=%d.%d.%d <%d.%d.%d", v.Major, v.Minor, v.Patch, v.Major, v.Minor, v.Patch+1)
+	}
+}
+
+// Check allows a redeploy from current to next unless it is a downgrade
+// or a breaking (major) bump outside the policy's range, in which case it
+// returns an error. force bypasses both checks, for operators who know
+// what they're doing.
+func (policy *VersionPolicy) Check(current, next semver.Version, force bool) error {
+	if force {
+		return nil
+	}
+
+	if next.LT(current) {
+		return errors.New(fmt.Sprintf("Refusing to downgrade chaincode from %s to %s; pass --force to override", current, next))
+	}
+
+	if policy != nil && !policy.allowed(next) {
+		return errors.New(fmt.Sprintf("Version %s is outside the allowed range '%s'; pass --force to override", next, policy.constraint))
+	}
+
+	return nil
+}
+
+// deployedVersion records a chainlet's currently deployed semantic
+// version and the minimum prior version it declares itself compatible
+// with, so a peer can refuse invocations made against an incompatible
+// earlier deployment.
+type deployedVersion struct {
+	version              semver.Version
+	minCompatibleVersion semver.Version
+}
+
+// versionRegistry tracks the currently deployed version of every
+// chainlet, keyed by ChainletID.Url. It is reachable concurrently from
+// gRPC Deploy calls, so access to deployed is guarded by mu.
+type versionRegistry struct {
+	mu       sync.RWMutex
+	deployed map[string]deployedVersion
+}
+
+func newVersionRegistry() *versionRegistry {
+	return &versionRegistry{deployed: make(map[string]deployedVersion)}
+}
+
+// checkAndRecord validates that version may be deployed over whatever is
+// currently deployed at url, then records it as the new deployed version.
+// minCompatible is the chaincode's declared MinCompatibleVersion, used by
+// CheckInvocationCompatibility.
+//
+// If policy is nil, the allowed range defaults to a caret range anchored
+// on the currently deployed version (e.g. "^1.2.0"), so that downgrades
+// and breaking major bumps are rejected by default rather than only when
+// an operator has bothered to configure an explicit policy; an explicit
+// policy may still be supplied to override that default.
+func (r *versionRegistry) checkAndRecord(url string, version semver.Version, minCompatible semver.Version, policy *VersionPolicy, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.deployed[url]; ok {
+		effective := policy
+		if effective == nil {
+			derived, err := NewVersionPolicy(fmt.Sprintf("^%d.%d.%d", current.version.Major, current.version.Minor, current.version.Patch))
+			if err != nil {
+				return err
+			}
+			effective = derived
+		}
+
+		if err := effective.Check(current.version, version, force); err != nil {
+			return err
+		}
+	}
+
+	r.deployed[url] = deployedVersion{version: version, minCompatibleVersion: minCompatible}
+	return nil
+}
+
+// get returns the currently deployed version for url.
+func (r *versionRegistry) get(url string) (semver.Version, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dv, ok := r.deployed[url]
+	if !ok {
+		return semver.Version{}, errors.New(fmt.Sprintf("No version deployed for %s", url))
+	}
+	return dv.version, nil
+}
+
+// CheckInvocationCompatibility is the migration hook an invocation path
+// can call before dispatching a transaction: it refuses invocations made
+// against a chainlet version older than whatever MinCompatibleVersion the
+// currently deployed chainlet declares.
+func (r *versionRegistry) CheckInvocationCompatibility(url string, invokedVersion semver.Version) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dv, ok := r.deployed[url]
+	if !ok {
+		return errors.New(fmt.Sprintf("No version deployed for %s", url))
+	}
+
+	if invokedVersion.LT(dv.minCompatibleVersion) {
+		return errors.New(fmt.Sprintf("Chainlet %s version %s is no longer compatible; minimum supported version is %s", url, invokedVersion, dv.minCompatibleVersion))
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package openchain
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+func TestNewVersionPolicyCaretRange(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allow      string
+		reject     string
+	}{
+		{"^1.2.0", "1.2.5", "2.0.0"},
+		{"^0.2.3", "0.2.9", "0.3.0"},
+		{"^0.0.3", "0.0.3", "0.0.4"},
+		{">=2.0.0 <3.0.0", "2.5.0", "3.0.0"},
+	}
+
+	for _, tt := range tests {
+		policy, err := NewVersionPolicy(tt.constraint)
+		if err != nil {
+			t.Fatalf("NewVersionPolicy(%q) returned error: %s", tt.constraint, err)
+		}
+
+		allow := semver.MustParse(tt.allow)
+		if !policy.allowed(allow) {
+			t.Errorf("NewVersionPolicy(%q): expected %s to be allowed", tt.constraint, tt.allow)
+		}
+
+		reject := semver.MustParse(tt.reject)
+		if policy.allowed(reject) {
+			t.Errorf("NewVersionPolicy(%q): expected %s to be rejected", tt.constraint, tt.reject)
+		}
+	}
+}
+
+func TestVersionRegistryCheckAndRecord(t *testing.T) {
+	const url = "github.com/openblockchain/chaincode/example"
+
+	r := newVersionRegistry()
+
+	// First deploy to a never-before-seen URL always succeeds.
+	if err := r.checkAndRecord(url, semver.MustParse("1.0.0"), semver.MustParse("1.0.0"), nil, false); err != nil {
+		t.Fatalf("first deploy should succeed, got: %s", err)
+	}
+
+	// A minor/patch bump on top of an already-deployed version must be
+	// allowed by default, with no VersionPolicy configured. This is the
+	// exact regression the ^-range parse failure introduced: every
+	// redeploy, not just breaking ones, used to fail here.
+	if err := r.checkAndRecord(url, semver.MustParse("1.1.0"), semver.MustParse("1.0.0"), nil, false); err != nil {
+		t.Fatalf("compatible minor bump should be allowed by default, got: %s", err)
+	}
+
+	// A breaking major bump must be rejected by default.
+	if err := r.checkAndRecord(url, semver.MustParse("2.0.0"), semver.MustParse("1.0.0"), nil, false); err == nil {
+		t.Fatalf("breaking major bump should be rejected by default")
+	}
+
+	// ...unless force is set.
+	if err := r.checkAndRecord(url, semver.MustParse("2.0.0"), semver.MustParse("1.0.0"), nil, true); err != nil {
+		t.Fatalf("forced major bump should be allowed, got: %s", err)
+	}
+
+	// A downgrade must be rejected even with a permissive policy.
+	permissive, err := NewVersionPolicy(">=0.0.0")
+	if err != nil {
+		t.Fatalf("NewVersionPolicy returned error: %s", err)
+	}
+	if err := r.checkAndRecord(url, semver.MustParse("1.9.0"), semver.MustParse("1.0.0"), permissive, false); err == nil {
+		t.Fatalf("downgrade should be rejected even under a permissive policy")
+	}
+
+	got, err := r.get(url)
+	if err != nil {
+		t.Fatalf("get returned error: %s", err)
+	}
+	if got.String() != "2.0.0" {
+		t.Errorf("expected deployed version 2.0.0, got %s", got)
+	}
+}